@@ -1,17 +1,16 @@
 package core
 
 import (
+	"bytes"
 	_ "embed"
 	"fmt"
 	"io"
 	"log"
 	"os"
-	"text/template"
 
 	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/cuecontext"
 	"cuelang.org/go/cue/load"
-	"cuelang.org/go/encoding/yaml"
 )
 
 //go:embed schema.cue
@@ -83,37 +82,30 @@ func TraverseFields(val cue.Value, out io.Writer) error {
 	return nil
 }
 
-const yamlTemplate = `# Do not edit, this is autogenerated from cue
+const defaultHeader = "Do not edit, this is autogenerated from cue"
 
-{{ .Content }}`
-
-func WriteYAML(val cue.Value, filename string) error {
-	yamlBytes, err := yaml.Encode(val)
+// WriteYAML renders val to filename using the yaml Encoder, honoring
+// opts for dry-run/diff/idempotent-write behavior. It is kept for
+// backwards compatibility with callers that only ever dumped YAML; new
+// code should go through EncoderFor and Encoder.Encode directly so it
+// can pick a format per output.
+func WriteYAML(val cue.Value, filename string, opts RenderOptions) (bool, error) {
+	enc, err := EncoderFor("yaml")
 	if err != nil {
-		return fmt.Errorf("failed to encode to YAML: %w", err)
+		return false, err
 	}
 
-	tmpl, err := template.New("yaml").Parse(yamlTemplate)
-	if err != nil {
-		return fmt.Errorf("failed to parse YAML template: %w", err)
+	var buf bytes.Buffer
+	if err := enc.Encode(val, &buf, defaultHeader); err != nil {
+		return false, fmt.Errorf("failed to write YAML file: %w", err)
 	}
 
-	f, err := os.Create(filename)
+	changed, err := writeRendered(filename, buf.Bytes(), opts)
 	if err != nil {
-		return fmt.Errorf("failed to create YAML file: %w", err)
-	}
-	defer f.Close()
-
-	data := map[string]string{
-		"Content": string(yamlBytes),
+		return false, fmt.Errorf("failed to write YAML file: %w", err)
 	}
 
-	err = tmpl.Execute(f, data)
-	if err != nil {
-		return fmt.Errorf("failed to write YAML file: %w", err)
-	}
-
-	return nil
+	return changed, nil
 }
 
 func Run() {
@@ -128,8 +120,12 @@ func Run() {
 		log.Fatal(err)
 	}
 
-	err = WriteYAML(val, "templates.yaml")
+	err = RenderAll(val, os.Stdout, RenderOptions{})
 	if err != nil {
 		log.Fatal(err)
 	}
+
+	if _, err := WriteYAML(val, "templates.yaml", RenderOptions{}); err != nil {
+		log.Fatal(err)
+	}
 }
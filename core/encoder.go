@@ -0,0 +1,184 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// Encoder renders a CUE value in a specific output format.
+type Encoder interface {
+	// Encode writes val to w. header, if non-empty, is prepended as a
+	// comment in formats that support one; formats that don't (json)
+	// ignore it.
+	Encode(val cue.Value, w io.Writer, header string) error
+}
+
+var encoders = map[string]Encoder{
+	"yaml": yamlEncoder{},
+	"json": jsonEncoder{},
+	"toml": tomlEncoder{},
+	"hcl":  hclEncoder{},
+	"env":  envEncoder{},
+}
+
+// EncoderFor looks up the Encoder registered for format, defaulting to
+// yaml when format is empty.
+func EncoderFor(format string) (Encoder, error) {
+	if format == "" {
+		format = "yaml"
+	}
+
+	enc, ok := encoders[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown output format %q", format)
+	}
+
+	return enc, nil
+}
+
+func decodeToMap(val cue.Value) (map[string]interface{}, error) {
+	var m map[string]interface{}
+	if err := val.Decode(&m); err != nil {
+		return nil, fmt.Errorf("failed to decode CUE value: %w", err)
+	}
+	return m, nil
+}
+
+type jsonEncoder struct{}
+
+func (jsonEncoder) Encode(val cue.Value, w io.Writer, _ string) error {
+	b, err := val.MarshalJSON()
+	if err != nil {
+		return fmt.Errorf("failed to encode to JSON: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, b, "", "  "); err != nil {
+		return fmt.Errorf("failed to format JSON: %w", err)
+	}
+	buf.WriteByte('\n')
+
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+type tomlEncoder struct{}
+
+func (tomlEncoder) Encode(val cue.Value, w io.Writer, header string) error {
+	m, err := decodeToMap(val)
+	if err != nil {
+		return err
+	}
+
+	b, err := toml.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("failed to encode to TOML: %w", err)
+	}
+
+	return writeWithHeader(w, header, "#", b)
+}
+
+type hclEncoder struct{}
+
+func (hclEncoder) Encode(val cue.Value, w io.Writer, header string) error {
+	m, err := decodeToMap(val)
+	if err != nil {
+		return err
+	}
+
+	if err := writeWithHeader(w, header, "#", nil); err != nil {
+		return err
+	}
+
+	return writeHCLBody(w, m, 0)
+}
+
+func writeHCLBody(w io.Writer, m map[string]interface{}, depth int) error {
+	indent := ""
+	for i := 0; i < depth; i++ {
+		indent += "  "
+	}
+
+	for _, key := range sortedKeys(m) {
+		value := m[key]
+		switch v := value.(type) {
+		case map[string]interface{}:
+			if _, err := fmt.Fprintf(w, "%s%s {\n", indent, key); err != nil {
+				return err
+			}
+			if err := writeHCLBody(w, v, depth+1); err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintf(w, "%s}\n", indent); err != nil {
+				return err
+			}
+		default:
+			if _, err := fmt.Fprintf(w, "%s%s = %s\n", indent, key, hclLiteral(v)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func hclLiteral(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return fmt.Sprintf("%q", val)
+	case []interface{}:
+		items := make([]string, len(val))
+		for i, item := range val {
+			items[i] = hclLiteral(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	default:
+		return fmt.Sprintf("%v", val)
+	}
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+type envEncoder struct{}
+
+func (envEncoder) Encode(val cue.Value, w io.Writer, header string) error {
+	m, err := decodeToMap(val)
+	if err != nil {
+		return err
+	}
+
+	var body []byte
+	for _, key := range sortedKeys(m) {
+		value := m[key]
+		if _, ok := value.(map[string]interface{}); ok {
+			continue
+		}
+		body = append(body, []byte(fmt.Sprintf("%s=%v\n", key, value))...)
+	}
+
+	return writeWithHeader(w, header, "#", body)
+}
+
+func writeWithHeader(w io.Writer, header, commentPrefix string, body []byte) error {
+	if header != "" {
+		if _, err := fmt.Fprintf(w, "%s %s\n\n", commentPrefix, header); err != nil {
+			return err
+		}
+	}
+	_, err := w.Write(body)
+	return err
+}
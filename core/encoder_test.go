@@ -0,0 +1,76 @@
+package core
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"cuelang.org/go/cue/cuecontext"
+)
+
+// TestHCLEncoderDeterministicKeyOrder guards against reintroducing the
+// map-iteration-order bug fixed in sortedKeys: encoding the same data
+// repeatedly must always emit keys in the same order.
+func TestHCLEncoderDeterministicKeyOrder(t *testing.T) {
+	ctx := cuecontext.New()
+	val := ctx.CompileString(`
+zebra: "z"
+apple: "a"
+mango: "m"
+`)
+	if val.Err() != nil {
+		t.Fatalf("failed to compile CUE value: %v", val.Err())
+	}
+
+	var first string
+	for i := 0; i < 5; i++ {
+		var buf bytes.Buffer
+		if err := (hclEncoder{}).Encode(val, &buf, ""); err != nil {
+			t.Fatalf("Encode() error = %v", err)
+		}
+		if i == 0 {
+			first = buf.String()
+			continue
+		}
+		if buf.String() != first {
+			t.Fatalf("hclEncoder output is non-deterministic:\nrun 0:\n%s\nrun %d:\n%s", first, i, buf.String())
+		}
+	}
+
+	wantOrder := []string{"apple", "mango", "zebra"}
+	lastIdx := -1
+	for _, key := range wantOrder {
+		idx := strings.Index(first, key)
+		if idx < 0 {
+			t.Fatalf("expected output to contain key %q, got:\n%s", key, first)
+		}
+		if idx < lastIdx {
+			t.Errorf("expected sorted key order %v, got:\n%s", wantOrder, first)
+		}
+		lastIdx = idx
+	}
+}
+
+// TestEnvEncoderDeterministicKeyOrder is the env-format analog of
+// TestHCLEncoderDeterministicKeyOrder.
+func TestEnvEncoderDeterministicKeyOrder(t *testing.T) {
+	ctx := cuecontext.New()
+	val := ctx.CompileString(`
+zebra: "z"
+apple: "a"
+mango: "m"
+`)
+	if val.Err() != nil {
+		t.Fatalf("failed to compile CUE value: %v", val.Err())
+	}
+
+	var buf bytes.Buffer
+	if err := (envEncoder{}).Encode(val, &buf, ""); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	want := "apple=a\nmango=m\nzebra=z\n"
+	if buf.String() != want {
+		t.Errorf("envEncoder output = %q, want %q", buf.String(), want)
+	}
+}
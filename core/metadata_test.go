@@ -0,0 +1,141 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"testing"
+
+	"cuelang.org/go/cue/cuecontext"
+)
+
+func TestApplyFileMetadataAppliesMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.txt")
+	if err := os.WriteFile(path, []byte("x"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	ctx := cuecontext.New()
+	entry := ctx.CompileString(`mode: "0640"`)
+	if entry.Err() != nil {
+		t.Fatalf("failed to compile entry: %v", entry.Err())
+	}
+
+	if err := applyFileMetadata(entry, path); err != nil {
+		t.Fatalf("applyFileMetadata() error = %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if info.Mode().Perm() != 0o640 {
+		t.Errorf("mode = %v, want 0640", info.Mode().Perm())
+	}
+}
+
+func TestApplyFileMetadataWithoutModeOwnerGroupIsNoop(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.txt")
+	if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	ctx := cuecontext.New()
+	entry := ctx.CompileString(`path: "unused"`)
+	if entry.Err() != nil {
+		t.Fatalf("failed to compile entry: %v", entry.Err())
+	}
+
+	if err := applyFileMetadata(entry, path); err != nil {
+		t.Fatalf("applyFileMetadata() error = %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if info.Mode().Perm() != 0o644 {
+		t.Errorf("mode = %v, want unchanged 0644", info.Mode().Perm())
+	}
+}
+
+func TestChownPathToCurrentUserAndGroup(t *testing.T) {
+	current, err := user.Current()
+	if err != nil {
+		t.Skipf("cannot resolve current user: %v", err)
+	}
+	group, err := user.LookupGroupId(current.Gid)
+	if err != nil {
+		t.Skipf("cannot resolve current group: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "out.txt")
+	if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	if err := chownPath(path, current.Username, group.Name); err != nil {
+		t.Fatalf("chownPath() error = %v", err)
+	}
+}
+
+func TestChownPathUnknownUserErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.txt")
+	if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	err := chownPath(path, "this-user-should-not-exist", "")
+	if err == nil {
+		t.Fatalf("chownPath() error = nil, want an error for an unknown user")
+	}
+}
+
+// TestRenderAllAppliesMetadataEvenWhenContentUnchanged guards the
+// chunk0-6 fix: mode/owner/group must be re-applied on every render,
+// even when the rendered bytes are identical to what's already on
+// disk.
+func TestRenderAllAppliesMetadataEvenWhenContentUnchanged(t *testing.T) {
+	outPath := filepath.Join(t.TempDir(), "out.txt")
+
+	ctx := cuecontext.New()
+	srcFor := func(mode string) string {
+		return fmt.Sprintf(`
+entry: {
+	templates: [{
+		template: "same\n"
+		path:     %q
+		mode:     %q
+	}]
+}
+`, outPath, mode)
+	}
+
+	first := ctx.CompileString(srcFor("0644"))
+	if first.Err() != nil {
+		t.Fatalf("failed to compile CUE value: %v", first.Err())
+	}
+	var out bytes.Buffer
+	if err := RenderAll(first, &out, RenderOptions{}); err != nil {
+		t.Fatalf("RenderAll() error = %v", err)
+	}
+
+	second := ctx.CompileString(srcFor("0600"))
+	if second.Err() != nil {
+		t.Fatalf("failed to compile CUE value: %v", second.Err())
+	}
+	var out2 bytes.Buffer
+	if err := RenderAll(second, &out2, RenderOptions{}); err != nil {
+		t.Fatalf("RenderAll() error = %v", err)
+	}
+
+	info, err := os.Stat(outPath)
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("mode = %v, want 0600 even though the template content didn't change", info.Mode().Perm())
+	}
+}
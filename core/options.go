@@ -0,0 +1,74 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// RenderOptions controls how a rendered file is written to disk. It is
+// shared by RenderAll, WriteYAML, and RenderTemplatesDir so all three
+// entry points support the same dry-run/diff/idempotent-write behavior.
+type RenderOptions struct {
+	// DryRun renders to memory and writes a unified diff against the
+	// existing file to Diff instead of touching disk.
+	DryRun bool
+	// Diff receives the unified diff produced in DryRun mode. Ignored
+	// when DryRun is false.
+	Diff io.Writer
+	// OnlyIfChanged skips rewriting the file, preserving its mtime,
+	// when the rendered bytes are identical to what's already there.
+	OnlyIfChanged bool
+}
+
+// writeRendered writes content to path according to opts and reports
+// whether the file's contents actually changed, so callers such as the
+// per-template service restart hook can skip work when they didn't.
+func writeRendered(path string, content []byte, opts RenderOptions) (bool, error) {
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, fmt.Errorf("failed to read existing %s: %w", path, err)
+	}
+
+	changed := err != nil || !bytes.Equal(existing, content)
+
+	if opts.DryRun {
+		if changed && opts.Diff != nil {
+			if err := writeUnifiedDiff(opts.Diff, path, existing, content); err != nil {
+				return false, err
+			}
+		}
+		return changed, nil
+	}
+
+	if opts.OnlyIfChanged && !changed {
+		return false, nil
+	}
+
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		return false, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return changed, nil
+}
+
+func writeUnifiedDiff(w io.Writer, path string, before, after []byte) error {
+	diff := difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(before)),
+		B:        difflib.SplitLines(string(after)),
+		FromFile: path,
+		ToFile:   path,
+		Context:  3,
+	}
+
+	text, err := difflib.GetUnifiedDiffString(diff)
+	if err != nil {
+		return fmt.Errorf("failed to build diff for %s: %w", path, err)
+	}
+
+	_, err = io.WriteString(w, text)
+	return err
+}
@@ -0,0 +1,107 @@
+package core
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteRenderedWritesNewFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.txt")
+
+	changed, err := writeRendered(path, []byte("hello"), RenderOptions{})
+	if err != nil {
+		t.Fatalf("writeRendered() error = %v", err)
+	}
+	if !changed {
+		t.Errorf("writeRendered() changed = false, want true for a new file")
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if string(got) != "hello" {
+		t.Errorf("file content = %q, want %q", got, "hello")
+	}
+}
+
+func TestWriteRenderedOnlyIfChangedSkipsIdenticalContent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.txt")
+	if err := os.WriteFile(path, []byte("same"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	before := info.ModTime()
+
+	changed, err := writeRendered(path, []byte("same"), RenderOptions{OnlyIfChanged: true})
+	if err != nil {
+		t.Fatalf("writeRendered() error = %v", err)
+	}
+	if changed {
+		t.Errorf("writeRendered() changed = true, want false for identical content")
+	}
+
+	info, err = os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if !info.ModTime().Equal(before) {
+		t.Errorf("mtime changed from %v to %v, want untouched file", before, info.ModTime())
+	}
+}
+
+func TestWriteRenderedOnlyIfChangedWritesDifferentContent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.txt")
+	if err := os.WriteFile(path, []byte("old"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	changed, err := writeRendered(path, []byte("new"), RenderOptions{OnlyIfChanged: true})
+	if err != nil {
+		t.Fatalf("writeRendered() error = %v", err)
+	}
+	if !changed {
+		t.Errorf("writeRendered() changed = false, want true for different content")
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if string(got) != "new" {
+		t.Errorf("file content = %q, want %q", got, "new")
+	}
+}
+
+func TestWriteRenderedDryRunDoesNotTouchDisk(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.txt")
+	if err := os.WriteFile(path, []byte("old"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	var diff bytes.Buffer
+	changed, err := writeRendered(path, []byte("new"), RenderOptions{DryRun: true, Diff: &diff})
+	if err != nil {
+		t.Fatalf("writeRendered() error = %v", err)
+	}
+	if !changed {
+		t.Errorf("writeRendered() changed = false, want true for different content")
+	}
+	if diff.Len() == 0 {
+		t.Errorf("expected a unified diff to be written, got none")
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if string(got) != "old" {
+		t.Errorf("dry run modified file on disk: got %q, want %q", got, "old")
+	}
+}
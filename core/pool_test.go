@@ -0,0 +1,61 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"cuelang.org/go/cue/cuecontext"
+)
+
+// TestRenderAllWithNonPositiveWorkersDoesNotDeadlock guards against
+// reintroducing the bug fixed alongside WithWorkers: a worker count of 0
+// used to be forwarded straight to errgroup.Group.SetLimit, which hangs
+// RenderAll forever, and a negative count removed the limit altogether,
+// instead of either falling back to the default pool size.
+func TestRenderAllWithNonPositiveWorkersDoesNotDeadlock(t *testing.T) {
+	for _, n := range []int{0, -1} {
+		n := n
+		t.Run(fmt.Sprintf("workers=%d", n), func(t *testing.T) {
+			dir := t.TempDir()
+			outPath := filepath.Join(dir, "out.txt")
+
+			ctx := cuecontext.New()
+			src := fmt.Sprintf(`
+entry: {
+	templates: [{
+		template: "ok\n"
+		path:     %q
+	}]
+}
+`, outPath)
+
+			val := ctx.CompileString(src)
+			if val.Err() != nil {
+				t.Fatalf("failed to compile CUE value: %v", val.Err())
+			}
+
+			done := make(chan error, 1)
+			go func() {
+				var out bytes.Buffer
+				done <- RenderAll(val, &out, RenderOptions{}, WithWorkers(n))
+			}()
+
+			select {
+			case err := <-done:
+				if err != nil {
+					t.Fatalf("RenderAll() error = %v", err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("RenderAll(WithWorkers(%d)) did not return, likely deadlocked", n)
+			}
+
+			if _, err := os.Stat(outPath); err != nil {
+				t.Errorf("expected %s to be written: %v", outPath, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,278 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/user"
+	"runtime"
+	"strconv"
+	"text/template"
+
+	"cuelang.org/go/cue"
+	"golang.org/x/sync/errgroup"
+)
+
+// PoolOption configures the worker pool RenderAll fans its rendering
+// work out across.
+type PoolOption func(*poolConfig)
+
+type poolConfig struct {
+	workers int
+}
+
+// WithWorkers overrides the default pool size of runtime.GOMAXPROCS(0).
+// n <= 0 is ignored rather than passed through: errgroup.Group.SetLimit
+// treats 0 as "allow zero running goroutines," which would deadlock
+// RenderAll forever instead of running serially.
+func WithWorkers(n int) PoolOption {
+	return func(c *poolConfig) {
+		if n <= 0 {
+			return
+		}
+		c.workers = n
+	}
+}
+
+type renderJob struct {
+	key   string
+	entry cue.Value
+	data  cue.Value
+}
+
+// RenderAll walks the templates list on each top-level field of val and
+// renders every entry across a worker pool, applying the optional mode,
+// owner, group, and service post-render hooks. Because CUE field and
+// list iteration order is stable, results are collected into a slice
+// indexed by that order and flushed to out only once every job has
+// finished, so log output stays deterministic regardless of which
+// goroutine happens to finish first.
+func RenderAll(val cue.Value, out io.Writer, renderOpts RenderOptions, opts ...PoolOption) error {
+	cfg := poolConfig{workers: runtime.GOMAXPROCS(0)}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	jobs, err := collectRenderJobs(val)
+	if err != nil {
+		return err
+	}
+
+	logLines := make([]string, len(jobs))
+
+	g := new(errgroup.Group)
+	g.SetLimit(cfg.workers)
+
+	for i, job := range jobs {
+		i, job := i, job
+		g.Go(func() error {
+			line, err := renderTemplateEntry(job.entry, job.data, renderOpts)
+			if err != nil {
+				return fmt.Errorf("failed to render template for %s: %w", job.key, err)
+			}
+			logLines[i] = line
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return err
+	}
+
+	for _, line := range logLines {
+		fmt.Fprint(out, line)
+	}
+
+	return nil
+}
+
+func collectRenderJobs(val cue.Value) ([]renderJob, error) {
+	iter, err := val.Fields()
+	if err != nil {
+		return nil, fmt.Errorf("failed to iterate over CUE fields: %w", err)
+	}
+
+	var jobs []renderJob
+	for iter.Next() {
+		key := iter.Label()
+		value := iter.Value()
+
+		templatesIter, err := value.LookupPath(cue.ParsePath("templates")).List()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get templates list for %s: %w", key, err)
+		}
+
+		for templatesIter.Next() {
+			jobs = append(jobs, renderJob{key: key, entry: templatesIter.Value(), data: value})
+		}
+	}
+
+	return jobs, nil
+}
+
+func renderTemplateEntry(entry, data cue.Value, opts RenderOptions) (string, error) {
+	path, err := entry.LookupPath(cue.ParsePath("path")).String()
+	if err != nil {
+		return "", fmt.Errorf("failed to get path string: %w", err)
+	}
+
+	var buf bytes.Buffer
+
+	if templateVal := entry.LookupPath(cue.ParsePath("template")); templateVal.Exists() {
+		templateStr, err := templateVal.String()
+		if err != nil {
+			return "", fmt.Errorf("failed to get template string: %w", err)
+		}
+
+		tmpl, err := template.New(path).Parse(templateStr)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse template for %s: %w", path, err)
+		}
+
+		dataMap, err := decodeToMap(data)
+		if err != nil {
+			return "", fmt.Errorf("failed to decode data for %s: %w", path, err)
+		}
+
+		if err := tmpl.Execute(&buf, dataMap); err != nil {
+			return "", fmt.Errorf("failed to render %s: %w", path, err)
+		}
+	} else {
+		enc, err := EncoderFor(lookupOptionalString(entry, "format"))
+		if err != nil {
+			return "", fmt.Errorf("failed to select encoder for %s: %w", path, err)
+		}
+
+		// Encode the entry's own fields, leaving out its templates list.
+		fields := data.Context().CompileString("{}")
+		iter, err := data.Fields()
+		if err != nil {
+			return "", fmt.Errorf("failed to iterate fields for %s: %w", path, err)
+		}
+		for iter.Next() {
+			if iter.Label() == "templates" {
+				continue
+			}
+			fields = fields.FillPath(cue.MakePath(iter.Selector()), iter.Value())
+		}
+
+		if err := enc.Encode(fields, &buf, ""); err != nil {
+			return "", fmt.Errorf("failed to encode %s: %w", path, err)
+		}
+	}
+
+	changed, err := writeRendered(path, buf.Bytes(), opts)
+	if err != nil {
+		return "", err
+	}
+
+	if opts.DryRun {
+		if changed {
+			return fmt.Sprintf("diff %s\n", path), nil
+		}
+		return fmt.Sprintf("unchanged %s\n", path), nil
+	}
+
+	// Mode/owner/group apply every render, not just when content
+	// changed: the file may have been created or had its permissions
+	// drift out-of-band since the last render, and CUE may grow a
+	// mode/owner/group field for a template whose content hasn't moved.
+	if err := applyFileMetadata(entry, path); err != nil {
+		return "", err
+	}
+
+	if !changed {
+		return fmt.Sprintf("unchanged %s\n", path), nil
+	}
+
+	if err := runServiceHook(entry, path); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("wrote %s\n", path), nil
+}
+
+func applyFileMetadata(entry cue.Value, path string) error {
+	if modeVal := entry.LookupPath(cue.ParsePath("mode")); modeVal.Exists() {
+		modeStr, err := modeVal.String()
+		if err != nil {
+			return fmt.Errorf("failed to read mode for %s: %w", path, err)
+		}
+		mode, err := strconv.ParseUint(modeStr, 8, 32)
+		if err != nil {
+			return fmt.Errorf("invalid mode %q for %s: %w", modeStr, path, err)
+		}
+		if err := os.Chmod(path, os.FileMode(mode)); err != nil {
+			return fmt.Errorf("failed to chmod %s: %w", path, err)
+		}
+	}
+
+	owner := lookupOptionalString(entry, "owner")
+	group := lookupOptionalString(entry, "group")
+	if owner != "" || group != "" {
+		if err := chownPath(path, owner, group); err != nil {
+			return fmt.Errorf("failed to chown %s: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+func lookupOptionalString(entry cue.Value, field string) string {
+	v := entry.LookupPath(cue.ParsePath(field))
+	if !v.Exists() {
+		return ""
+	}
+	s, err := v.String()
+	if err != nil {
+		return ""
+	}
+	return s
+}
+
+func chownPath(path, owner, group string) error {
+	uid := -1
+	gid := -1
+
+	if owner != "" {
+		u, err := user.Lookup(owner)
+		if err != nil {
+			return fmt.Errorf("failed to look up user %s: %w", owner, err)
+		}
+		uid, err = strconv.Atoi(u.Uid)
+		if err != nil {
+			return fmt.Errorf("invalid uid for user %s: %w", owner, err)
+		}
+	}
+
+	if group != "" {
+		g, err := user.LookupGroup(group)
+		if err != nil {
+			return fmt.Errorf("failed to look up group %s: %w", group, err)
+		}
+		gid, err = strconv.Atoi(g.Gid)
+		if err != nil {
+			return fmt.Errorf("invalid gid for group %s: %w", group, err)
+		}
+	}
+
+	return os.Chown(path, uid, gid)
+}
+
+// runServiceHook restarts the service named on a template entry, if any,
+// after its file has been rendered and its metadata applied.
+func runServiceHook(entry cue.Value, path string) error {
+	service := lookupOptionalString(entry, "service")
+	if service == "" {
+		return nil
+	}
+
+	cmd := exec.Command("systemctl", "restart", service)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to restart service %s for %s: %w", service, path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+)
+
+// TestRenderAllRendersAgainstEntryData proves that a template can read a
+// field other than "templates" off its own entry, end to end: the CUE
+// value is validated against the real #Data schema (so #Entry's "..."
+// is exercised, not just RenderAll in isolation) and then rendered.
+func TestRenderAllRendersAgainstEntryData(t *testing.T) {
+	outPath := filepath.Join(t.TempDir(), "web.conf")
+
+	ctx := cuecontext.New()
+	src := fmt.Sprintf(`
+web: {
+	port: 8080
+	templates: [{
+		template: "port={{ .port }}\n"
+		path:     %q
+	}]
+}
+`, outPath)
+
+	val := ctx.CompileString(src)
+	if val.Err() != nil {
+		t.Fatalf("failed to compile CUE value: %v", val.Err())
+	}
+
+	schema := ctx.CompileString(schemaFile)
+	if schema.Err() != nil {
+		t.Fatalf("failed to compile schema: %v", schema.Err())
+	}
+
+	schemaVal := schema.LookupPath(cue.ParsePath("#Data"))
+	val = val.Unify(schemaVal)
+	if err := val.Validate(); err != nil {
+		t.Fatalf("validation failed (an entry should be able to carry fields alongside templates): %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := RenderAll(val, &out, RenderOptions{}); err != nil {
+		t.Fatalf("RenderAll() error = %v", err)
+	}
+
+	got, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+
+	want := "port=8080\n"
+	if string(got) != want {
+		t.Errorf("rendered content = %q, want %q", got, want)
+	}
+}
+
+// TestRenderAllFormatOnlyEntryEncodesRealData proves a template-less,
+// format-only entry serializes its entry's own config fields, not just
+// its own templates list.
+func TestRenderAllFormatOnlyEntryEncodesRealData(t *testing.T) {
+	outPath := filepath.Join(t.TempDir(), "web.json")
+
+	ctx := cuecontext.New()
+	src := fmt.Sprintf(`
+web: {
+	host: "example.com"
+	port: 8080
+	templates: [{
+		format: "json"
+		path:   %q
+	}]
+}
+`, outPath)
+
+	val := ctx.CompileString(src)
+	if val.Err() != nil {
+		t.Fatalf("failed to compile CUE value: %v", val.Err())
+	}
+
+	var out bytes.Buffer
+	if err := RenderAll(val, &out, RenderOptions{}); err != nil {
+		t.Fatalf("RenderAll() error = %v", err)
+	}
+
+	got, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+
+	for _, want := range []string{`"host": "example.com"`, `"port": 8080`} {
+		if !bytes.Contains(got, []byte(want)) {
+			t.Errorf("rendered JSON = %s, want it to contain %q", got, want)
+		}
+	}
+
+	if bytes.Contains(got, []byte("templates")) {
+		t.Errorf("rendered JSON = %s, should not contain the entry's own templates list", got)
+	}
+}
+
@@ -0,0 +1,144 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/sprig/v3"
+	yamlv3 "gopkg.in/yaml.v3"
+
+	"cuelang.org/go/cue"
+)
+
+const templateExt = ".tpl"
+
+// RenderOption configures RenderTemplatesDir.
+type RenderOption func(*renderConfig)
+
+type renderConfig struct {
+	leftDelim  string
+	rightDelim string
+}
+
+// WithDelims overrides the default "{{"/"}}" template delimiters, for
+// templates embedded in files that also use Go-template-like syntax of
+// their own (e.g. Helm charts).
+func WithDelims(left, right string) RenderOption {
+	return func(c *renderConfig) {
+		c.leftDelim = left
+		c.rightDelim = right
+	}
+}
+
+// RenderTemplatesDir walks templateDir for files ending in .tpl, renders
+// each against val using the Sprig function library plus a handful of
+// CUE-aware helpers, and writes the result under outputDir at the same
+// subpath with the .tpl suffix stripped. renderOpts controls dry-run,
+// diffing, and idempotent-write behavior for every file it writes.
+func RenderTemplatesDir(templateDir, outputDir string, val cue.Value, renderOpts RenderOptions, opts ...RenderOption) error {
+	cfg := renderConfig{leftDelim: "{{", rightDelim: "}}"}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return filepath.WalkDir(templateDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(path) != templateExt {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(templateDir, path)
+		if err != nil {
+			return fmt.Errorf("failed to resolve relative path for %s: %w", path, err)
+		}
+
+		outPath := filepath.Join(outputDir, strings.TrimSuffix(relPath, templateExt))
+		return renderTemplateFile(path, outPath, val, cfg, renderOpts)
+	})
+}
+
+func renderTemplateFile(tplPath, outPath string, val cue.Value, cfg renderConfig, renderOpts RenderOptions) error {
+	body, err := os.ReadFile(tplPath)
+	if err != nil {
+		return fmt.Errorf("failed to read template %s: %w", tplPath, err)
+	}
+
+	tmpl := template.New(filepath.Base(tplPath)).
+		Delims(cfg.leftDelim, cfg.rightDelim).
+		Funcs(sprig.TxtFuncMap()).
+		Funcs(templateHelpers())
+
+	tmpl, err = tmpl.Parse(string(body))
+	if err != nil {
+		return fmt.Errorf("failed to parse template %s: %w", tplPath, err)
+	}
+
+	data, err := decodeToMap(val)
+	if err != nil {
+		return fmt.Errorf("failed to decode data for %s: %w", tplPath, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("failed to render %s: %w", tplPath, err)
+	}
+
+	if !renderOpts.DryRun {
+		if err := os.MkdirAll(filepath.Dir(outPath), 0o755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", outPath, err)
+		}
+	}
+
+	if _, err := writeRendered(outPath, buf.Bytes(), renderOpts); err != nil {
+		return fmt.Errorf("failed to write %s: %w", outPath, err)
+	}
+
+	return nil
+}
+
+// templateHelpers returns the CUE-aware helpers layered on top of Sprig:
+// toYaml/toJson for re-serializing decoded template data, indent for
+// nesting them under a YAML key, quote for shell/YAML-safe quoting, and
+// env for reading process environment variables. Since the template
+// root (and any sub-value pulled from it via dot-notation) is decoded
+// Go data rather than a cue.Value by the time these run, toYaml/toJson
+// take interface{} and marshal with the same yaml.v3/encoding/json
+// packages used elsewhere, instead of the alphabetizing, comment-losing
+// cuelang.org/go/encoding/yaml encoder.
+func templateHelpers() template.FuncMap {
+	return template.FuncMap{
+		"toYaml": func(v interface{}) (string, error) {
+			b, err := yamlv3.Marshal(v)
+			if err != nil {
+				return "", err
+			}
+			return strings.TrimSuffix(string(b), "\n"), nil
+		},
+		"toJson": func(v interface{}) (string, error) {
+			b, err := json.Marshal(v)
+			if err != nil {
+				return "", err
+			}
+			return string(b), nil
+		},
+		"indent": func(spaces int, s string) string {
+			pad := strings.Repeat(" ", spaces)
+			lines := strings.Split(s, "\n")
+			for i, line := range lines {
+				lines[i] = pad + line
+			}
+			return strings.Join(lines, "\n")
+		},
+		"quote": func(s string) string {
+			return fmt.Sprintf("%q", s)
+		},
+		"env": os.Getenv,
+	}
+}
@@ -0,0 +1,121 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"cuelang.org/go/cue/cuecontext"
+)
+
+func TestRenderTemplatesDirWalksTreeWithSprigAndHelpers(t *testing.T) {
+	templateDir := t.TempDir()
+	outputDir := t.TempDir()
+
+	topTpl := "name={{ .name | upper }}\nquoted={{ quote .name }}\n"
+	if err := os.WriteFile(filepath.Join(templateDir, "top.conf.tpl"), []byte(topTpl), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	nestedDir := filepath.Join(templateDir, "nested")
+	if err := os.MkdirAll(nestedDir, 0o755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	nestedTpl := "port={{ .port }}\n"
+	if err := os.WriteFile(filepath.Join(nestedDir, "app.conf.tpl"), []byte(nestedTpl), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	// A non-.tpl file alongside the templates should be left alone.
+	if err := os.WriteFile(filepath.Join(templateDir, "README.md"), []byte("ignore me"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	ctx := cuecontext.New()
+	val := ctx.CompileString(`
+name: "web"
+port: 8080
+`)
+	if val.Err() != nil {
+		t.Fatalf("failed to compile CUE value: %v", val.Err())
+	}
+
+	if err := RenderTemplatesDir(templateDir, outputDir, val, RenderOptions{}); err != nil {
+		t.Fatalf("RenderTemplatesDir() error = %v", err)
+	}
+
+	top, err := os.ReadFile(filepath.Join(outputDir, "top.conf"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	wantTop := "name=WEB\nquoted=\"web\"\n"
+	if string(top) != wantTop {
+		t.Errorf("top.conf = %q, want %q", top, wantTop)
+	}
+
+	nested, err := os.ReadFile(filepath.Join(outputDir, "nested", "app.conf"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	wantNested := "port=8080\n"
+	if string(nested) != wantNested {
+		t.Errorf("nested/app.conf = %q, want %q", nested, wantNested)
+	}
+
+	if _, err := os.Stat(filepath.Join(outputDir, "README.md")); !os.IsNotExist(err) {
+		t.Errorf("expected non-.tpl files to be skipped entirely, but README.md was written, stat err = %v", err)
+	}
+}
+
+func TestRenderTemplatesDirWithDelims(t *testing.T) {
+	templateDir := t.TempDir()
+	outputDir := t.TempDir()
+
+	tpl := "name=[[ .name ]]\n"
+	if err := os.WriteFile(filepath.Join(templateDir, "app.conf.tpl"), []byte(tpl), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	ctx := cuecontext.New()
+	val := ctx.CompileString(`name: "web"`)
+	if val.Err() != nil {
+		t.Fatalf("failed to compile CUE value: %v", val.Err())
+	}
+
+	if err := RenderTemplatesDir(templateDir, outputDir, val, RenderOptions{}, WithDelims("[[", "]]")); err != nil {
+		t.Fatalf("RenderTemplatesDir() error = %v", err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(outputDir, "app.conf"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	want := "name=web\n"
+	if string(got) != want {
+		t.Errorf("app.conf = %q, want %q", got, want)
+	}
+}
+
+func TestRenderTemplatesDirDryRunDoesNotWriteFiles(t *testing.T) {
+	templateDir := t.TempDir()
+	outputDir := t.TempDir()
+
+	tpl := "name={{ .name }}\n"
+	if err := os.WriteFile(filepath.Join(templateDir, "app.conf.tpl"), []byte(tpl), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	ctx := cuecontext.New()
+	val := ctx.CompileString(`name: "web"`)
+	if val.Err() != nil {
+		t.Fatalf("failed to compile CUE value: %v", val.Err())
+	}
+
+	if err := RenderTemplatesDir(templateDir, outputDir, val, RenderOptions{DryRun: true}); err != nil {
+		t.Fatalf("RenderTemplatesDir() error = %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(outputDir, "app.conf")); !os.IsNotExist(err) {
+		t.Errorf("expected dry run not to write app.conf, stat err = %v", err)
+	}
+}
@@ -0,0 +1,166 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"cuelang.org/go/cue"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// yamlEncoder renders a CUE value to YAML by walking it into a yaml.v3
+// Node tree rather than going through cuelang.org/go/encoding/yaml,
+// which emits struct fields in alphabetical order and drops comments.
+// Building the Node tree ourselves preserves CUE's declaration order
+// and carries @comment(...) attributes through as head comments.
+type yamlEncoder struct{}
+
+func (yamlEncoder) Encode(val cue.Value, w io.Writer, header string) error {
+	node, err := buildYAMLNode(val)
+	if err != nil {
+		return fmt.Errorf("failed to encode to YAML: %w", err)
+	}
+
+	if header != "" {
+		node.HeadComment = yamlComment(header)
+	}
+
+	enc := yamlv3.NewEncoder(w)
+	enc.SetIndent(2)
+	defer enc.Close()
+
+	if err := enc.Encode(node); err != nil {
+		return fmt.Errorf("failed to encode to YAML: %w", err)
+	}
+
+	return nil
+}
+
+func buildYAMLNode(val cue.Value) (*yamlv3.Node, error) {
+	switch val.IncompleteKind() {
+	case cue.StructKind:
+		return buildYAMLMapping(val)
+	case cue.ListKind:
+		return buildYAMLSequence(val)
+	default:
+		return buildYAMLScalar(val)
+	}
+}
+
+func buildYAMLMapping(val cue.Value) (*yamlv3.Node, error) {
+	node := &yamlv3.Node{Kind: yamlv3.MappingNode, Tag: "!!map"}
+
+	iter, err := val.Fields()
+	if err != nil {
+		return nil, fmt.Errorf("failed to iterate struct fields: %w", err)
+	}
+
+	for iter.Next() {
+		fieldVal := iter.Value()
+
+		keyNode := &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: iter.Label()}
+		if comment := fieldComment(fieldVal); comment != "" {
+			keyNode.HeadComment = yamlComment(comment)
+		}
+
+		valNode, err := buildYAMLNode(fieldVal)
+		if err != nil {
+			return nil, err
+		}
+
+		node.Content = append(node.Content, keyNode, valNode)
+	}
+
+	return node, nil
+}
+
+func buildYAMLSequence(val cue.Value) (*yamlv3.Node, error) {
+	node := &yamlv3.Node{Kind: yamlv3.SequenceNode, Tag: "!!seq"}
+
+	listIter, err := val.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to iterate list: %w", err)
+	}
+
+	for listIter.Next() {
+		itemNode, err := buildYAMLNode(listIter.Value())
+		if err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content, itemNode)
+	}
+
+	return node, nil
+}
+
+func buildYAMLScalar(val cue.Value) (*yamlv3.Node, error) {
+	switch val.IncompleteKind() {
+	case cue.StringKind:
+		s, err := val.String()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read string value: %w", err)
+		}
+		return &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: s}, nil
+
+	case cue.IntKind:
+		i, err := val.Int64()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read int value: %w", err)
+		}
+		return &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!int", Value: strconv.FormatInt(i, 10)}, nil
+
+	case cue.FloatKind, cue.NumberKind:
+		f, err := val.Float64()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read float value: %w", err)
+		}
+		return &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!float", Value: strconv.FormatFloat(f, 'g', -1, 64)}, nil
+
+	case cue.BoolKind:
+		b, err := val.Bool()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bool value: %w", err)
+		}
+		return &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(b)}, nil
+
+	case cue.NullKind:
+		return &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!null", Value: "null"}, nil
+
+	default:
+		b, err := val.MarshalJSON()
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal value: %w", err)
+		}
+		return &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: string(b)}, nil
+	}
+}
+
+// yamlComment turns raw comment text into the "# "-prefixed form
+// yaml.v3 expects: it writes HeadComment/LineComment values verbatim,
+// so the "#" has to already be part of the string, on every line of a
+// multi-line comment.
+func yamlComment(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = "# " + line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// fieldComment returns the text of a field's @comment(...) CUE
+// attribute, if any, for use as a YAML head comment.
+func fieldComment(val cue.Value) string {
+	attr := val.Attribute("comment")
+	if attr.Err() != nil {
+		return ""
+	}
+
+	text, err := attr.String(0)
+	if err != nil {
+		return ""
+	}
+
+	return text
+}
@@ -0,0 +1,70 @@
+package core
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"cuelang.org/go/cue/cuecontext"
+)
+
+func TestYamlComment(t *testing.T) {
+	got := yamlComment("Do not edit, this is autogenerated from cue")
+	want := "# Do not edit, this is autogenerated from cue"
+	if got != want {
+		t.Errorf("yamlComment() = %q, want %q", got, want)
+	}
+
+	multi := yamlComment("line one\nline two")
+	wantMulti := "# line one\n# line two"
+	if multi != wantMulti {
+		t.Errorf("yamlComment() = %q, want %q", multi, wantMulti)
+	}
+}
+
+func TestYAMLEncoderPreservesFieldOrder(t *testing.T) {
+	ctx := cuecontext.New()
+	val := ctx.CompileString(`
+zebra: 1
+apple: 2
+mango: 3
+`)
+	if val.Err() != nil {
+		t.Fatalf("failed to compile CUE value: %v", val.Err())
+	}
+
+	var buf bytes.Buffer
+	if err := (yamlEncoder{}).Encode(val, &buf, ""); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	out := buf.String()
+	zebraIdx := strings.Index(out, "zebra:")
+	appleIdx := strings.Index(out, "apple:")
+	mangoIdx := strings.Index(out, "mango:")
+
+	if zebraIdx < 0 || appleIdx < 0 || mangoIdx < 0 {
+		t.Fatalf("expected all three keys in output, got:\n%s", out)
+	}
+
+	if !(zebraIdx < appleIdx && appleIdx < mangoIdx) {
+		t.Errorf("expected declaration order zebra, apple, mango, got:\n%s", out)
+	}
+}
+
+func TestYAMLEncoderHeaderIsCommented(t *testing.T) {
+	ctx := cuecontext.New()
+	val := ctx.CompileString(`foo: "bar"`)
+	if val.Err() != nil {
+		t.Fatalf("failed to compile CUE value: %v", val.Err())
+	}
+
+	var buf bytes.Buffer
+	if err := (yamlEncoder{}).Encode(val, &buf, "Do not edit"); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	if !strings.HasPrefix(buf.String(), "# Do not edit") {
+		t.Errorf("expected header to be emitted as a comment, got:\n%s", buf.String())
+	}
+}